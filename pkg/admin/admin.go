@@ -0,0 +1,81 @@
+// Package admin implements the service-role GoTrue admin endpoints
+// (auth/v1/admin) used for managing users out-of-band from the normal
+// sign-up/sign-in flow. Calls require a service key, never the public
+// anon key.
+package admin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/semvis123/supabase-go/pkg/auth"
+	"github.com/semvis123/supabase-go/pkg/transport"
+)
+
+const adminEndpoint = "auth/v1/admin"
+
+// Client is the public surface of the admin sub-package.
+type Client interface {
+	CreateUser(ctx context.Context, creds auth.Credentials) (*auth.User, error)
+	DeleteUser(ctx context.Context, userID string) error
+	ListUsers(ctx context.Context) ([]auth.User, error)
+}
+
+type client struct {
+	transport  *transport.Client
+	serviceKey string
+}
+
+// New builds the default admin.Client backed by t, authenticated with
+// serviceKey.
+func New(t *transport.Client, serviceKey string) Client {
+	return &client{transport: t, serviceKey: serviceKey}
+}
+
+func (c *client) endpoint(path string) string {
+	return fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(c.transport.BaseURL, "/"), adminEndpoint, path)
+}
+
+func (c *client) authorize(req *http.Request) {
+	req.Header.Set("Authorization", "Bearer "+c.serviceKey)
+}
+
+func (c *client) CreateUser(ctx context.Context, creds auth.Credentials) (*auth.User, error) {
+	req, err := c.transport.NewRequest(ctx, http.MethodPost, c.endpoint("users"), transport.JSONBody(creds))
+	if err != nil {
+		return nil, err
+	}
+	c.authorize(req)
+	req.Header.Set("Content-Type", "application/json")
+	user := &auth.User{}
+	if err := c.transport.SendRequest(ctx, req, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (c *client) DeleteUser(ctx context.Context, userID string) error {
+	req, err := c.transport.NewRequest(ctx, http.MethodDelete, c.endpoint("users/"+userID), nil)
+	if err != nil {
+		return err
+	}
+	c.authorize(req)
+	return c.transport.SendRequest(ctx, req, nil)
+}
+
+func (c *client) ListUsers(ctx context.Context) ([]auth.User, error) {
+	req, err := c.transport.NewRequest(ctx, http.MethodGet, c.endpoint("users"), nil)
+	if err != nil {
+		return nil, err
+	}
+	c.authorize(req)
+	var res struct {
+		Users []auth.User `json:"users"`
+	}
+	if err := c.transport.SendRequest(ctx, req, &res); err != nil {
+		return nil, err
+	}
+	return res.Users, nil
+}
@@ -0,0 +1,106 @@
+// Package auth implements the Supabase GoTrue (auth/v1) client. It is kept
+// separate from storage/admin/realtime so callers that only need one
+// subsystem don't pull in the others' transitive dependencies, and so
+// fakes implementing Client can be swapped in for tests.
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/semvis123/supabase-go/pkg/transport"
+)
+
+const authEndpoint = "auth/v1"
+
+// Credentials is the email/password pair accepted by SignUp and SignIn.
+type Credentials struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// Session is the access/refresh token pair returned by a successful
+// SignUp or SignIn.
+type Session struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+	User         User   `json:"user"`
+}
+
+type User struct {
+	ID    string `json:"id"`
+	Email string `json:"email"`
+}
+
+// Client is the public surface of the auth sub-package. The default
+// implementation is returned by New; tests can provide their own.
+type Client interface {
+	SignUp(ctx context.Context, creds Credentials) (*Session, error)
+	SignIn(ctx context.Context, creds Credentials) (*Session, error)
+	SignOut(ctx context.Context, accessToken string) error
+	User(ctx context.Context, accessToken string) (*User, error)
+}
+
+type client struct {
+	transport *transport.Client
+}
+
+// New builds the default auth.Client backed by t.
+func New(t *transport.Client) Client {
+	return &client{transport: t}
+}
+
+func (c *client) endpoint(path string) string {
+	return fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(c.transport.BaseURL, "/"), authEndpoint, path)
+}
+
+func (c *client) SignUp(ctx context.Context, creds Credentials) (*Session, error) {
+	req, err := c.transport.NewRequest(ctx, http.MethodPost, c.endpoint("signup"), transport.JSONBody(creds))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	session := &Session{}
+	if err := c.transport.SendRequest(ctx, req, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (c *client) SignIn(ctx context.Context, creds Credentials) (*Session, error) {
+	req, err := c.transport.NewRequest(ctx, http.MethodPost, c.endpoint("token?grant_type=password"), transport.JSONBody(creds))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	session := &Session{}
+	if err := c.transport.SendRequest(ctx, req, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (c *client) SignOut(ctx context.Context, accessToken string) error {
+	req, err := c.transport.NewRequest(ctx, http.MethodPost, c.endpoint("logout"), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	return c.transport.SendRequest(ctx, req, nil)
+}
+
+func (c *client) User(ctx context.Context, accessToken string) (*User, error) {
+	req, err := c.transport.NewRequest(ctx, http.MethodGet, c.endpoint("user"), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	user := &User{}
+	if err := c.transport.SendRequest(ctx, req, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
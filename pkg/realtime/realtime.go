@@ -0,0 +1,811 @@
+// Package realtime implements the Supabase Realtime (Phoenix channels)
+// client: broadcast, postgres_changes and presence over a websocket
+// connection, with automatic reconnection.
+package realtime
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	phxClose        = "phx_close"
+	phxError        = "phx_error"
+	phxJoin         = "phx_join"
+	phxReply        = "phx_reply"
+	phxLeave        = "phx_leave"
+	phxHeartbeat    = "heartbeat"
+	phxTopic        = "phoenix"
+	maxMessageBytes = 3_072_000
+
+	postgresChangesEvent = "postgres_changes"
+	presenceStateEvent   = "presence_state"
+	presenceDiffEvent    = "presence_diff"
+	presenceTrackEvent   = "track"
+	presenceUntrackEvent = "untrack"
+
+	defaultReadBufferSize  = 4096
+	defaultWriteBufferSize = 4096
+	defaultPingInterval    = 20 * time.Second
+	defaultPongWait        = 60 * time.Second
+	defaultWriteWait       = 10 * time.Second
+	defaultSendQueueSize   = 256
+	defaultJoinTimeout     = 10 * time.Second
+)
+
+var (
+	phxHeartbeatPayload = map[string]interface{}{"msg": "heartbeat"}
+)
+
+// ChannelConfig tunes the underlying websocket connection used by every
+// Channel created from a Realtime instance. The zero value is replaced with
+// sane defaults by DefaultChannelConfig.
+type ChannelConfig struct {
+	ReadBufferSize  int
+	WriteBufferSize int
+	// PingInterval is how often the client pings the server; the server is
+	// expected to respond with a pong before PongWait elapses.
+	PingInterval time.Duration
+	PongWait     time.Duration
+	WriteWait    time.Duration
+	// SendQueueSize bounds the number of outbound messages buffered between
+	// Send/SendAndWait callers and the write pump.
+	SendQueueSize int
+	TLSConfig     *tls.Config
+	Dialer        *websocket.Dialer
+	Reconnect     ReconnectPolicy
+	// JoinTimeout bounds how long open() waits for the server's phx_reply
+	// to the join it sends on every (re)connect, defaulting to
+	// defaultJoinTimeout if zero.
+	JoinTimeout time.Duration
+}
+
+func (cfg ChannelConfig) joinTimeout() time.Duration {
+	if cfg.JoinTimeout > 0 {
+		return cfg.JoinTimeout
+	}
+	return defaultJoinTimeout
+}
+
+// ReconnectPolicy controls the exponential backoff applied after a
+// heartbeat failure or a dropped connection. Delay doubles (times
+// Multiplier) after each attempt, capped at MaxDelay, with up to Jitter
+// fraction of random variance added to avoid thundering-herd reconnects.
+// MaxAttempts <= 0 means retry forever.
+type ReconnectPolicy struct {
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+	Multiplier   float64
+	Jitter       float64
+	MaxAttempts  int
+}
+
+// DefaultReconnectPolicy returns the policy used when a ChannelConfig is
+// built via DefaultChannelConfig.
+func DefaultReconnectPolicy() ReconnectPolicy {
+	return ReconnectPolicy{
+		InitialDelay: 500 * time.Millisecond,
+		MaxDelay:     30 * time.Second,
+		Multiplier:   2,
+		Jitter:       0.2,
+		MaxAttempts:  0,
+	}
+}
+
+func (p ReconnectPolicy) delay(attempt int) time.Duration {
+	d := float64(p.InitialDelay) * pow(p.Multiplier, attempt)
+	if max := float64(p.MaxDelay); d > max {
+		d = max
+	}
+	if p.Jitter > 0 {
+		d += d * p.Jitter * (rand.Float64()*2 - 1)
+	}
+	if d < 0 {
+		d = 0
+	}
+	return time.Duration(d)
+}
+
+func pow(base float64, exp int) float64 {
+	result := 1.0
+	for i := 0; i < exp; i++ {
+		result *= base
+	}
+	return result
+}
+
+// DefaultChannelConfig returns the ChannelConfig used when Realtime.Channel
+// is called without a prior call to Realtime.SetChannelConfig.
+func DefaultChannelConfig() ChannelConfig {
+	return ChannelConfig{
+		ReadBufferSize:  defaultReadBufferSize,
+		WriteBufferSize: defaultWriteBufferSize,
+		PingInterval:    defaultPingInterval,
+		PongWait:        defaultPongWait,
+		WriteWait:       defaultWriteWait,
+		SendQueueSize:   defaultSendQueueSize,
+		JoinTimeout:     defaultJoinTimeout,
+		Reconnect:       DefaultReconnectPolicy(),
+	}
+}
+
+func (cfg ChannelConfig) dialer() *websocket.Dialer {
+	if cfg.Dialer != nil {
+		return cfg.Dialer
+	}
+	return &websocket.Dialer{
+		ReadBufferSize:  cfg.ReadBufferSize,
+		WriteBufferSize: cfg.WriteBufferSize,
+		TLSClientConfig: cfg.TLSConfig,
+	}
+}
+
+type PostgresChangesConfig struct {
+	Event  string `json:"event"`
+	Schema string `json:"schema"`
+	Table  string `json:"table"`
+	Filter string `json:"filter,omitempty"`
+}
+
+type PostgresChangePayload struct {
+	Type            string                 `json:"type"`
+	Schema          string                 `json:"schema"`
+	Table           string                 `json:"table"`
+	CommitTimestamp string                 `json:"commit_timestamp"`
+	Columns         []PostgresColumn       `json:"columns"`
+	Old             map[string]interface{} `json:"old_record"`
+	New             map[string]interface{} `json:"record"`
+}
+
+type PostgresColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type PresenceEvent struct {
+	Key   string                   `json:"key"`
+	State []map[string]interface{} `json:"-"`
+}
+
+type presenceDiffPayload struct {
+	Joins  map[string]presenceMeta `json:"joins"`
+	Leaves map[string]presenceMeta `json:"leaves"`
+}
+
+type presenceMeta struct {
+	Metas []map[string]interface{} `json:"metas"`
+}
+
+type Message struct {
+	Event   string                 `json:"event"`
+	Payload map[string]interface{} `json:"payload"`
+	Ref     *string                `json:"ref"`
+	Topic   string                 `json:"topic"`
+}
+
+// Client is the public surface of the realtime sub-package.
+type Client interface {
+	Channel(topic string) *Channel
+	ChannelWithUrl(topic string, websocketUrl string) *Channel
+	SetChannelConfig(cfg ChannelConfig)
+}
+
+type client struct {
+	baseURL       string
+	apiKey        string
+	channelConfig ChannelConfig
+}
+
+// New builds the default realtime.Client for a project at baseURL,
+// authenticated with apiKey (the anon or service key).
+func New(baseURL string, apiKey string) Client {
+	return &client{baseURL: baseURL, apiKey: apiKey}
+}
+
+// SetChannelConfig overrides the ChannelConfig used by every Channel
+// subsequently created with Channel or ChannelWithUrl.
+func (r *client) SetChannelConfig(cfg ChannelConfig) {
+	r.channelConfig = cfg
+}
+
+type Channel struct {
+	Topic             string
+	Url               string
+	Origin            string
+	Config            ChannelConfig
+	listeners         []Listener
+	postgresBindings  []PostgresChangesConfig
+	postgresCallbacks map[string][]func(*Channel, *PostgresChangePayload)
+	presence          map[string][]map[string]interface{}
+	onPresenceSync    []func(*Channel, map[string][]map[string]interface{})
+	onPresenceJoin    []func(*Channel, PresenceEvent)
+	onPresenceLeave   []func(*Channel, PresenceEvent)
+	ws                *websocket.Conn
+	connected         atomic.Bool
+	send              chan []byte
+	// done is closed exactly once, by Close, so every write pump - live or
+	// stale, running or not yet started - observes it without anyone having
+	// to be there to receive on it.
+	done              chan struct{}
+	errors            chan error
+	refCounter        uint64
+	pendingMu         sync.Mutex
+	pending           map[string]chan *Message
+	OnDisconnect      func(*Channel)
+	OnConnect         func(*Channel)
+
+	// openMu serializes open(), so a reconnect racing a caller-triggered
+	// Send/SendAndWait open() never dials two connections at once.
+	openMu sync.Mutex
+	// lifecycleMu guards generation and reconnecting below.
+	lifecycleMu sync.Mutex
+	// generation increments every successful open() so pumps started
+	// against an older connection can recognise they're stale once a
+	// newer one exists and stand down instead of tearing down the
+	// replacement.
+	generation uint64
+	// reconnecting is true between a pump noticing a dead connection and
+	// open() either succeeding or scheduleReconnect giving up, so only one
+	// of the read/write pump ever kicks off a reconnect for a given drop.
+	reconnecting bool
+	closed       atomic.Bool
+}
+
+func newChannel(topic string, url string, cfg ChannelConfig) *Channel {
+	return &Channel{
+		Topic:             topic,
+		Url:               url,
+		Origin:            "http://localhost/",
+		Config:            cfg,
+		postgresCallbacks: make(map[string][]func(*Channel, *PostgresChangePayload)),
+		presence:          make(map[string][]map[string]interface{}),
+		send:              make(chan []byte, cfg.SendQueueSize),
+		done:              make(chan struct{}),
+		errors:            make(chan error, 1),
+		pending:           make(map[string]chan *Message),
+		OnDisconnect:      func(*Channel) {},
+		OnConnect:         func(*Channel) {},
+	}
+}
+
+// Connected reports whether the channel currently has a live websocket
+// connection. It's safe to call from any goroutine.
+func (c *Channel) Connected() bool {
+	return c.connected.Load()
+}
+
+// Errors returns a channel that receives a terminal error when the
+// configured ReconnectPolicy's MaxAttempts is exhausted. The channel is
+// unbuffered-but-for-one and best-effort: callers that don't read from it
+// won't block the channel's internal goroutines.
+func (c *Channel) Errors() <-chan error {
+	return c.errors
+}
+
+func (c *Channel) nextRef() string {
+	return strconv.FormatUint(atomic.AddUint64(&c.refCounter, 1), 10)
+}
+
+type Listener struct {
+	EventName string
+	callback  func(*Channel, *Message)
+}
+
+func (r *client) Channel(topic string) *Channel {
+	websocketUrl := r.baseURL
+	websocketUrl = strings.Replace(websocketUrl, "https://", "wss://", 1)
+	websocketUrl = strings.Replace(websocketUrl, "http://", "ws://", 1)
+	websocketUrl = fmt.Sprintf("%s/realtime/v1/websocket?apikey=%s&vsn=1.0.0", websocketUrl, r.apiKey)
+	return newChannel(topic, websocketUrl, r.effectiveConfig())
+}
+
+func (r *client) ChannelWithUrl(topic string, websocketUrl string) *Channel {
+	return newChannel(topic, websocketUrl, r.effectiveConfig())
+}
+
+func (r *client) effectiveConfig() ChannelConfig {
+	if r.channelConfig == (ChannelConfig{}) {
+		return DefaultChannelConfig()
+	}
+	return r.channelConfig
+}
+
+func (c *Channel) OnPostgresChange(event string, schema string, table string, filter string, callback func(*Channel, *PostgresChangePayload)) {
+	binding := PostgresChangesConfig{Event: event, Schema: schema, Table: table, Filter: filter}
+	c.postgresBindings = append(c.postgresBindings, binding)
+	key := postgresBindingKey(binding)
+	c.postgresCallbacks[key] = append(c.postgresCallbacks[key], callback)
+}
+
+func postgresBindingKey(b PostgresChangesConfig) string {
+	return strings.Join([]string{b.Event, b.Schema, b.Table, b.Filter}, ":")
+}
+
+func (c *Channel) Track(state map[string]interface{}) error {
+	return c.Send(presenceTrackEvent, map[string]interface{}{
+		"type":    "presence",
+		"event":   presenceTrackEvent,
+		"payload": state,
+	})
+}
+
+func (c *Channel) Untrack() error {
+	return c.Send(presenceUntrackEvent, map[string]interface{}{
+		"type":  "presence",
+		"event": presenceUntrackEvent,
+	})
+}
+
+func (c *Channel) OnPresenceSync(callback func(*Channel, map[string][]map[string]interface{})) {
+	c.onPresenceSync = append(c.onPresenceSync, callback)
+}
+
+func (c *Channel) OnPresenceJoin(callback func(*Channel, PresenceEvent)) {
+	c.onPresenceJoin = append(c.onPresenceJoin, callback)
+}
+
+func (c *Channel) OnPresenceLeave(callback func(*Channel, PresenceEvent)) {
+	c.onPresenceLeave = append(c.onPresenceLeave, callback)
+}
+
+func (c *Channel) Listen() error {
+	return c.open()
+}
+
+// Send enqueues a message for delivery by the write pump. It never writes
+// to the underlying connection directly, so it's safe to call concurrently
+// with the heartbeat ping and other Send calls.
+func (c *Channel) Send(event string, payload map[string]interface{}) error {
+	ref := c.nextRef()
+	msg := &Message{Topic: c.Topic, Event: event, Payload: payload, Ref: &ref}
+	msgBytes, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if !c.connected.Load() {
+		if err := c.open(); err != nil {
+			return err
+		}
+	}
+	c.send <- msgBytes
+	return nil
+}
+
+// SendAndWait sends event/payload like Send, but blocks until the matching
+// phx_reply arrives (correlated via Message.Ref) or ctx is done. It's the
+// reliable way to confirm a join, a presence track, or a broadcast.
+func (c *Channel) SendAndWait(ctx context.Context, event string, payload map[string]interface{}) (*Message, error) {
+	ref := c.nextRef()
+	msg := &Message{Topic: c.Topic, Event: event, Payload: payload, Ref: &ref}
+	msgBytes, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	if !c.connected.Load() {
+		if err := c.open(); err != nil {
+			return nil, err
+		}
+	}
+
+	reply := make(chan *Message, 1)
+	c.pendingMu.Lock()
+	c.pending[ref] = reply
+	c.pendingMu.Unlock()
+	defer func() {
+		c.pendingMu.Lock()
+		delete(c.pending, ref)
+		c.pendingMu.Unlock()
+	}()
+
+	select {
+	case c.send <- msgBytes:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	select {
+	case resp := <-reply:
+		return resp, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Close marks the channel closed and tears down any live connection. It
+// never blocks: done is closed rather than sent on, so every write pump
+// observes it and closes its connection (which ends that connection's read
+// pump), while open and scheduleReconnect check the closed flag before
+// dialing, with no pump required to be there to receive it.
+func (c *Channel) Close() {
+	if !c.closed.CompareAndSwap(false, true) {
+		return
+	}
+	close(c.done)
+}
+
+func (c *Channel) joinConfig() map[string]interface{} {
+	config := map[string]interface{}{
+		"broadcast": map[string]interface{}{
+			"self": true,
+		},
+		"presence": map[string]interface{}{
+			"key": "",
+		},
+	}
+	if len(c.postgresBindings) > 0 {
+		config["postgres_changes"] = c.postgresBindings
+	}
+	return config
+}
+
+// open dials a new connection and replaces c.ws with it. It's serialized by
+// openMu so a reconnect racing a caller-triggered Send/SendAndWait open()
+// never runs two dials concurrently; if another goroutine already
+// reconnected by the time this one gets the lock, it's a no-op.
+func (c *Channel) open() error {
+	c.openMu.Lock()
+	defer c.openMu.Unlock()
+
+	if c.closed.Load() {
+		return fmt.Errorf("realtime: channel %q is closed", c.Topic)
+	}
+	if c.connected.Load() {
+		return nil
+	}
+
+	if c.ws != nil {
+		c.ws.Close()
+	}
+	header := http.Header{}
+	header.Set("Origin", c.Origin)
+	ws, _, err := c.Config.dialer().Dial(c.Url, header)
+	if err != nil {
+		return err
+	}
+
+	ws.SetReadDeadline(time.Now().Add(c.Config.PongWait))
+	ws.SetPongHandler(func(string) error {
+		return ws.SetReadDeadline(time.Now().Add(c.Config.PongWait))
+	})
+
+	joinRef := c.nextRef()
+	msg := &Message{Topic: c.Topic, Event: phxJoin, Ref: &joinRef, Payload: map[string]interface{}{
+		"config": c.joinConfig(),
+	}}
+	msgBytes, err := json.Marshal(msg)
+	if err != nil {
+		panic("incorrect join message configured")
+	}
+
+	// Register the join's ref before writing it so the reply can't arrive
+	// and be dropped before deliverReply has anywhere to route it to.
+	joinReply := make(chan *Message, 1)
+	c.pendingMu.Lock()
+	c.pending[joinRef] = joinReply
+	c.pendingMu.Unlock()
+	defer func() {
+		c.pendingMu.Lock()
+		delete(c.pending, joinRef)
+		c.pendingMu.Unlock()
+	}()
+
+	if err := ws.WriteMessage(websocket.TextMessage, msgBytes); err != nil {
+		ws.Close()
+		return err
+	}
+
+	c.lifecycleMu.Lock()
+	c.generation++
+	gen := c.generation
+	c.reconnecting = false
+	c.lifecycleMu.Unlock()
+
+	c.ws = ws
+	c.connected.Store(true)
+
+	go c.readPump(gen, ws)
+	go c.writePump(gen, ws)
+
+	select {
+	case reply := <-joinReply:
+		if status, _ := reply.Payload["status"].(string); status == "error" {
+			c.connected.Store(false)
+			ws.Close()
+			return fmt.Errorf("realtime: join %q rejected: %v", c.Topic, reply.Payload)
+		}
+	case <-time.After(c.Config.joinTimeout()):
+		c.connected.Store(false)
+		ws.Close()
+		return fmt.Errorf("realtime: join %q timed out waiting for phx_reply", c.Topic)
+	}
+
+	c.endReconnect()
+	c.OnConnect(c)
+	return nil
+}
+
+// beginReconnect reports whether the caller (a pump that just observed a
+// dead connection on gen) should be the one driving the reconnect: it's
+// false if gen is no longer the live generation (a newer connection has
+// already replaced it) or another pump already started reconnecting for
+// the same drop.
+func (c *Channel) beginReconnect(gen uint64) bool {
+	c.lifecycleMu.Lock()
+	defer c.lifecycleMu.Unlock()
+	if gen != c.generation || c.reconnecting {
+		return false
+	}
+	c.reconnecting = true
+	return true
+}
+
+func (c *Channel) endReconnect() {
+	c.lifecycleMu.Lock()
+	c.reconnecting = false
+	c.lifecycleMu.Unlock()
+}
+
+// readPump owns reads from the websocket connection and dispatches decoded
+// messages to listeners and presence/postgres_changes handlers. Only this
+// goroutine ever calls ws.ReadMessage on the ws it was handed; gen
+// identifies which generation of connection that is.
+func (c *Channel) readPump(gen uint64, ws *websocket.Conn) {
+	for {
+		_, data, err := ws.ReadMessage()
+		if err != nil {
+			if c.closed.Load() || !c.beginReconnect(gen) {
+				return
+			}
+			c.connected.Store(false)
+			ws.Close()
+			c.OnDisconnect(c)
+			go c.scheduleReconnect()
+			return
+		}
+		message := &Message{}
+		if err := json.Unmarshal(data, message); err != nil {
+			continue // ignore errors
+		}
+		if message.Event == phxReply {
+			c.deliverReply(message)
+			continue
+		}
+		if c.handlePostgresChange(message) {
+			continue
+		}
+		if c.handlePresence(message) {
+			continue
+		}
+		for _, l := range c.listeners {
+			if l.EventName == message.Event || l.EventName == "*" {
+				l.callback(c, message)
+			}
+		}
+	}
+}
+
+// writePump owns writes to the websocket connection: queued messages from
+// Send/SendAndWait and periodic pings. Only this goroutine ever calls
+// ws.WriteMessage on the ws it was handed; gen identifies which generation
+// of connection that is.
+func (c *Channel) writePump(gen uint64, ws *websocket.Conn) {
+	ticker := time.NewTicker(c.Config.PingInterval)
+	defer ticker.Stop()
+
+	fail := func() {
+		if c.closed.Load() || !c.beginReconnect(gen) {
+			return
+		}
+		c.connected.Store(false)
+		ws.Close()
+		c.OnDisconnect(c)
+		go c.scheduleReconnect()
+	}
+
+	for {
+		select {
+		case <-c.done:
+			c.connected.Store(false)
+			ws.SetWriteDeadline(time.Now().Add(c.Config.WriteWait))
+			ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+			ws.Close()
+			c.OnDisconnect(c)
+			return
+		case msgBytes, ok := <-c.send:
+			if !ok {
+				return
+			}
+			ws.SetWriteDeadline(time.Now().Add(c.Config.WriteWait))
+			if err := ws.WriteMessage(websocket.TextMessage, msgBytes); err != nil {
+				fail()
+				return
+			}
+		case <-ticker.C:
+			ws.SetWriteDeadline(time.Now().Add(c.Config.WriteWait))
+			if err := ws.WriteMessage(websocket.PingMessage, nil); err != nil {
+				fail()
+				return
+			}
+			// Phoenix channels are kept alive by an application-level
+			// heartbeat, not by the ws control pong above; without it the
+			// server's own heartbeat timeout drops the channel even though
+			// the websocket itself looks healthy.
+			ref := c.nextRef()
+			heartbeat := &Message{Topic: phxTopic, Event: phxHeartbeat, Payload: phxHeartbeatPayload, Ref: &ref}
+			heartbeatBytes, err := json.Marshal(heartbeat)
+			if err != nil {
+				fail()
+				return
+			}
+			ws.SetWriteDeadline(time.Now().Add(c.Config.WriteWait))
+			if err := ws.WriteMessage(websocket.TextMessage, heartbeatBytes); err != nil {
+				fail()
+				return
+			}
+		}
+	}
+}
+
+// scheduleReconnect backs off according to c.Config.Reconnect and retries
+// c.open until it succeeds or MaxAttempts is exhausted, in which case a
+// terminal error is delivered on c.Errors(). The caller must have already
+// won beginReconnect, so exactly one goroutine ever runs this per drop.
+func (c *Channel) scheduleReconnect() {
+	policy := c.Config.Reconnect
+	if policy == (ReconnectPolicy{}) {
+		policy = DefaultReconnectPolicy()
+	}
+	attempt := 0
+	for {
+		if c.closed.Load() {
+			c.endReconnect()
+			return
+		}
+		if policy.MaxAttempts > 0 && attempt >= policy.MaxAttempts {
+			c.endReconnect()
+			select {
+			case c.errors <- fmt.Errorf("realtime: giving up reconnecting to %q after %d attempts", c.Topic, attempt):
+			default:
+			}
+			return
+		}
+		time.Sleep(policy.delay(attempt))
+		if err := c.open(); err == nil {
+			return
+		}
+		attempt++
+	}
+}
+
+func (c *Channel) deliverReply(message *Message) {
+	if message.Ref == nil {
+		return
+	}
+	c.pendingMu.Lock()
+	reply, ok := c.pending[*message.Ref]
+	c.pendingMu.Unlock()
+	if ok {
+		reply <- message
+	}
+}
+
+func (c *Channel) handlePostgresChange(message *Message) bool {
+	if message.Event != postgresChangesEvent {
+		return false
+	}
+	data, ok := message.Payload["data"]
+	if !ok {
+		return true
+	}
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		return true
+	}
+	payload := &PostgresChangePayload{}
+	if err := json.Unmarshal(dataBytes, payload); err != nil {
+		return true
+	}
+	for key, callbacks := range c.postgresCallbacks {
+		binding := postgresBindingFromKey(key)
+		if !postgresBindingMatches(binding, payload) {
+			continue
+		}
+		for _, cb := range callbacks {
+			cb(c, payload)
+		}
+	}
+	return true
+}
+
+func postgresBindingFromKey(key string) PostgresChangesConfig {
+	parts := strings.SplitN(key, ":", 4)
+	for len(parts) < 4 {
+		parts = append(parts, "")
+	}
+	return PostgresChangesConfig{Event: parts[0], Schema: parts[1], Table: parts[2], Filter: parts[3]}
+}
+
+func postgresBindingMatches(binding PostgresChangesConfig, payload *PostgresChangePayload) bool {
+	if binding.Event != "*" && !strings.EqualFold(binding.Event, payload.Type) {
+		return false
+	}
+	if binding.Schema != "" && binding.Schema != payload.Schema {
+		return false
+	}
+	if binding.Table != "" && binding.Table != payload.Table {
+		return false
+	}
+	return true
+}
+
+func (c *Channel) handlePresence(message *Message) bool {
+	switch message.Event {
+	case presenceStateEvent:
+		state := make(map[string][]map[string]interface{})
+		payloadBytes, err := json.Marshal(message.Payload)
+		if err != nil {
+			return true
+		}
+		raw := make(map[string]presenceMeta)
+		if err := json.Unmarshal(payloadBytes, &raw); err != nil {
+			return true
+		}
+		for key, meta := range raw {
+			state[key] = meta.Metas
+		}
+		c.presence = state
+		for _, cb := range c.onPresenceSync {
+			cb(c, c.presence)
+		}
+		return true
+	case presenceDiffEvent:
+		payloadBytes, err := json.Marshal(message.Payload)
+		if err != nil {
+			return true
+		}
+		diff := presenceDiffPayload{}
+		if err := json.Unmarshal(payloadBytes, &diff); err != nil {
+			return true
+		}
+		for key, meta := range diff.Joins {
+			c.presence[key] = meta.Metas
+			for _, cb := range c.onPresenceJoin {
+				cb(c, PresenceEvent{Key: key, State: meta.Metas})
+			}
+		}
+		for key, meta := range diff.Leaves {
+			delete(c.presence, key)
+			for _, cb := range c.onPresenceLeave {
+				cb(c, PresenceEvent{Key: key, State: meta.Metas})
+			}
+		}
+		for _, cb := range c.onPresenceSync {
+			cb(c, c.presence)
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *Channel) On(event string, callback func(*Channel, *Message)) {
+	c.listeners = append(c.listeners, Listener{event, callback})
+}
+
+func (c *Channel) RemoveCallbacksForEvent(event string) {
+	c.listeners = slices.DeleteFunc(c.listeners, func(l Listener) bool {
+		return l.EventName == event
+	})
+}
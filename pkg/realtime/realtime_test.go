@@ -0,0 +1,112 @@
+package realtime
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// newJoinTestServer starts a websocket server that replies to the first
+// message it receives (the client's phx_join) with a phx_reply carrying the
+// given status, correlated via the join's own ref.
+func newJoinTestServer(t *testing.T, status string) *httptest.Server {
+	t.Helper()
+	upgrader := websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+
+		var join Message
+		if err := ws.ReadJSON(&join); err != nil {
+			return
+		}
+		reply := Message{
+			Topic:   join.Topic,
+			Event:   phxReply,
+			Ref:     join.Ref,
+			Payload: map[string]interface{}{"status": status},
+		}
+		ws.WriteJSON(reply)
+		// Keep the connection open so the caller's pumps don't immediately
+		// see a read error and start reconnecting mid-test.
+		for {
+			if _, _, err := ws.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func wsURL(httpURL string) string {
+	return "ws" + strings.TrimPrefix(httpURL, "http")
+}
+
+func TestListenConfirmsJoinOk(t *testing.T) {
+	srv := newJoinTestServer(t, "ok")
+
+	ch := newChannel("room", wsURL(srv.URL), DefaultChannelConfig())
+	if err := ch.Listen(); err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	defer ch.Close()
+
+	if !ch.Connected() {
+		t.Fatal("Connected() = false after a successful join")
+	}
+}
+
+func TestListenFailsOnJoinError(t *testing.T) {
+	srv := newJoinTestServer(t, "error")
+
+	ch := newChannel("room", wsURL(srv.URL), DefaultChannelConfig())
+	err := ch.Listen()
+	if err == nil {
+		t.Fatal("Listen: want error when the server rejects the join, got nil")
+	}
+}
+
+func TestCloseDoesNotBlockBeforeListen(t *testing.T) {
+	ch := newChannel("room", "ws://127.0.0.1:0", DefaultChannelConfig())
+
+	done := make(chan struct{})
+	go func() {
+		ch.Close()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Close() blocked with no pump ever having run")
+	}
+}
+
+func TestCloseDoesNotBlockAfterConnect(t *testing.T) {
+	srv := newJoinTestServer(t, "ok")
+
+	ch := newChannel("room", wsURL(srv.URL), DefaultChannelConfig())
+	if err := ch.Listen(); err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ch.Close()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Close() blocked with a live writePump that should have drained it")
+	}
+}
@@ -0,0 +1,350 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	tusResumableVersion = "1.0.0"
+	resumableUploadPath = "storage/v1/upload/resumable"
+
+	defaultChunkSize = 6 * 1024 * 1024
+)
+
+// ResumableUploadState is the minimal state needed to continue an
+// interrupted resumable upload in a later process: where the upload lives
+// and how much of it the server already has.
+type ResumableUploadState struct {
+	UploadURL string
+	Offset    int64
+}
+
+// ResumeStore persists ResumableUploadState between UploadResumable calls,
+// keyed by "<bucket>/<path>", so an interrupted upload can be resumed from
+// a different process.
+type ResumeStore interface {
+	Save(ctx context.Context, key string, state ResumableUploadState) error
+	Load(ctx context.Context, key string) (ResumableUploadState, bool, error)
+}
+
+// UploadResumableOptions configures UploadResumable.
+type UploadResumableOptions struct {
+	ContentType string
+	// ChunkSize defaults to 6MB if zero.
+	ChunkSize int64
+	// Parallelism > 1 splits the upload into that many independent partial
+	// uploads (via the TUS concatenation extension) uploaded concurrently,
+	// then concatenates them server-side. 0 or 1 means a single sequential
+	// stream.
+	Parallelism int
+	ResumeStore ResumeStore
+	// Progress, if set, is called after every chunk with the total bytes
+	// uploaded so far and the overall size.
+	Progress func(uploaded int64, total int64)
+}
+
+func (o UploadResumableOptions) chunkSize() int64 {
+	if o.ChunkSize > 0 {
+		return o.ChunkSize
+	}
+	return defaultChunkSize
+}
+
+// UploadResumable uploads src (size bytes long) to bucket/path using the
+// TUS 1.0.0 resumable upload protocol against
+// storage/v1/upload/resumable. If opts.ResumeStore has state for this
+// bucket/path from a previous, interrupted call, the upload continues from
+// the last acknowledged offset instead of starting over.
+func (c *client) UploadResumable(ctx context.Context, bucket string, path string, src io.ReaderAt, size int64, opts UploadResumableOptions) error {
+	if opts.Parallelism > 1 {
+		return c.uploadResumableParallel(ctx, bucket, path, src, size, opts)
+	}
+
+	key := resumeKey(bucket, path)
+	uploadURL, offset, err := c.resumeOrCreate(ctx, key, bucket, path, size, opts, "")
+	if err != nil {
+		return err
+	}
+
+	for offset < size {
+		newOffset, err := c.uploadChunk(ctx, uploadURL, src, offset, size, opts)
+		if err != nil {
+			return err
+		}
+		offset = newOffset
+		if opts.ResumeStore != nil {
+			if err := opts.ResumeStore.Save(ctx, key, ResumableUploadState{UploadURL: uploadURL, Offset: offset}); err != nil {
+				return err
+			}
+		}
+		if opts.Progress != nil {
+			opts.Progress(offset, size)
+		}
+	}
+	return nil
+}
+
+func resumeKey(bucket string, path string) string {
+	return bucket + "/" + strings.TrimPrefix(path, "/")
+}
+
+// resumeOrCreate returns an upload URL and the offset to resume from,
+// either from opts.ResumeStore (re-validated against the server via HEAD)
+// or by creating a brand new TUS upload. concat is the Upload-Concat header
+// value to use if a fresh upload has to be created ("partial" for one
+// stream of a parallel upload, "" for a normal single-stream upload).
+func (c *client) resumeOrCreate(ctx context.Context, key string, bucket string, path string, size int64, opts UploadResumableOptions, concat string) (string, int64, error) {
+	if opts.ResumeStore != nil {
+		if state, ok, err := opts.ResumeStore.Load(ctx, key); err != nil {
+			return "", 0, err
+		} else if ok {
+			offset, err := c.headOffset(ctx, state.UploadURL)
+			if err == nil {
+				return state.UploadURL, offset, nil
+			}
+			// the stored upload is gone or expired server-side; fall through
+			// and create a fresh one.
+		}
+	}
+
+	uploadURL, err := c.createResumableUpload(ctx, bucket, path, size, opts.ContentType, concat)
+	if err != nil {
+		return "", 0, err
+	}
+	return uploadURL, 0, nil
+}
+
+// createResumableUpload issues the TUS creation POST and returns the
+// Location URL the server assigned the upload. concat is the
+// Upload-Concat header value ("partial" when used as one stream of a
+// parallel upload, "" for a normal single-stream upload).
+func (c *client) createResumableUpload(ctx context.Context, bucket string, path string, size int64, contentType string, concat string) (string, error) {
+	url := fmt.Sprintf("%s/%s", strings.TrimSuffix(c.transport.BaseURL, "/"), resumableUploadPath)
+	req, err := c.transport.NewRequest(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Tus-Resumable", tusResumableVersion)
+	req.Header.Set("Upload-Length", strconv.FormatInt(size, 10))
+	req.Header.Set("Upload-Metadata", uploadMetadata(bucket, path, contentType))
+	if concat != "" {
+		req.Header.Set("Upload-Concat", concat)
+	}
+
+	res, err := c.transport.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("storage: resumable upload creation failed with status %d", res.StatusCode)
+	}
+	location := res.Header.Get("Location")
+	if location == "" {
+		return "", errors.New("storage: resumable upload creation response had no Location header")
+	}
+	return location, nil
+}
+
+func uploadMetadata(bucket string, path string, contentType string) string {
+	pairs := []string{
+		"bucketName " + base64.StdEncoding.EncodeToString([]byte(bucket)),
+		"objectName " + base64.StdEncoding.EncodeToString([]byte(path)),
+	}
+	if contentType != "" {
+		pairs = append(pairs, "contentType "+base64.StdEncoding.EncodeToString([]byte(contentType)))
+	}
+	return strings.Join(pairs, ",")
+}
+
+// headOffset asks the server how much of uploadURL it already has, per the
+// TUS HEAD request, so an interrupted upload can resume from the right
+// byte even if the caller's own bookkeeping is stale.
+func (c *client) headOffset(ctx context.Context, uploadURL string) (int64, error) {
+	req, err := c.transport.NewRequest(ctx, http.MethodHead, uploadURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Tus-Resumable", tusResumableVersion)
+
+	res, err := c.transport.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusNoContent {
+		return 0, fmt.Errorf("storage: resumable upload HEAD failed with status %d", res.StatusCode)
+	}
+	return strconv.ParseInt(res.Header.Get("Upload-Offset"), 10, 64)
+}
+
+// uploadChunk PATCHes one chunk starting at offset and returns the absolute
+// offset the upload should continue from next. On a 409 Conflict (the
+// server's offset disagrees with ours, e.g. after resuming in a new
+// process) it re-syncs via HEAD instead of trusting the offset the caller
+// passed in. TUS offsets only move forward on a non-empty chunk, so an
+// offset that ends up behind or equal to what we sent from indicates the
+// upload state is corrupt rather than something we can quietly resync past
+// or retry - we return an error instead of looping or letting the caller's
+// offset run backwards.
+func (c *client) uploadChunk(ctx context.Context, uploadURL string, src io.ReaderAt, offset int64, size int64, opts UploadResumableOptions) (int64, error) {
+	chunkSize := opts.chunkSize()
+	if remaining := size - offset; chunkSize > remaining {
+		chunkSize = remaining
+	}
+	buf := make([]byte, chunkSize)
+	n, err := src.ReadAt(buf, offset)
+	if err != nil && err != io.EOF {
+		return 0, err
+	}
+	if int64(n) != chunkSize {
+		return 0, fmt.Errorf("storage: read %d bytes at offset %d, want %d - source is shorter than the declared size %d", n, offset, chunkSize, size)
+	}
+
+	req, err := c.transport.NewRequest(ctx, http.MethodPatch, uploadURL, bytes.NewReader(buf))
+	if err != nil {
+		return 0, err
+	}
+	req.ContentLength = chunkSize
+	req.Header.Set("Tus-Resumable", tusResumableVersion)
+	req.Header.Set("Upload-Offset", strconv.FormatInt(offset, 10))
+	req.Header.Set("Content-Type", "application/offset+octet-stream")
+
+	res, err := c.transport.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusNoContent, http.StatusOK:
+		newOffset, err := strconv.ParseInt(res.Header.Get("Upload-Offset"), 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		if newOffset <= offset {
+			return 0, fmt.Errorf("storage: server reported offset %d, no further than the %d we just sent from", newOffset, offset)
+		}
+		return newOffset, nil
+	case http.StatusConflict:
+		serverOffset, err := c.headOffset(ctx, uploadURL)
+		if err != nil {
+			return 0, err
+		}
+		if serverOffset <= offset {
+			return 0, fmt.Errorf("storage: resumable upload state is inconsistent - server offset %d made no progress past local offset %d", serverOffset, offset)
+		}
+		return serverOffset, nil
+	default:
+		return 0, fmt.Errorf("storage: resumable chunk upload failed with status %d", res.StatusCode)
+	}
+}
+
+// uploadResumableParallel splits src into opts.Parallelism roughly-equal
+// partial uploads (TUS concatenation extension), uploads them
+// concurrently, then asks the server to concatenate them into the final
+// object. If opts.ResumeStore is set, each part's progress is persisted
+// under its own key (resumeKey(bucket, path) plus a ".partN" suffix) so an
+// interrupted parallel upload resumes part-by-part rather than restarting
+// from zero, the same way the sequential path resumes via resumeOrCreate.
+func (c *client) uploadResumableParallel(ctx context.Context, bucket string, path string, src io.ReaderAt, size int64, opts UploadResumableOptions) error {
+	parts := opts.Parallelism
+	partSize := size / int64(parts)
+	if partSize == 0 {
+		parts = 1
+		partSize = size
+	}
+
+	partURLs := make([]string, parts)
+	errs := make([]error, parts)
+	var wg sync.WaitGroup
+	var uploadedMu sync.Mutex
+	var uploaded int64
+
+	for i := 0; i < parts; i++ {
+		start := int64(i) * partSize
+		end := start + partSize
+		if i == parts-1 {
+			end = size
+		}
+
+		wg.Add(1)
+		go func(i int, start, end int64) {
+			defer wg.Done()
+			partPath := fmt.Sprintf("%s.part%d", path, i)
+			partKey := resumeKey(bucket, partPath)
+			partURL, offset, err := c.resumeOrCreate(ctx, partKey, bucket, partPath, end-start, opts, "partial")
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			if offset > 0 {
+				uploadedMu.Lock()
+				uploaded += offset
+				uploadedMu.Unlock()
+			}
+			partLen := end - start
+			for offset < partLen {
+				newOffset, err := c.uploadChunk(ctx, partURL, io.NewSectionReader(src, start, partLen), offset, partLen, opts)
+				if err != nil {
+					errs[i] = err
+					return
+				}
+				n := newOffset - offset
+				offset = newOffset
+				uploadedMu.Lock()
+				uploaded += n
+				if opts.Progress != nil {
+					opts.Progress(uploaded, size)
+				}
+				uploadedMu.Unlock()
+				if opts.ResumeStore != nil {
+					if err := opts.ResumeStore.Save(ctx, partKey, ResumableUploadState{UploadURL: partURL, Offset: offset}); err != nil {
+						errs[i] = err
+						return
+					}
+				}
+			}
+			partURLs[i] = partURL
+		}(i, start, end)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return c.concatenateParts(ctx, bucket, path, size, opts.ContentType, partURLs)
+}
+
+func (c *client) concatenateParts(ctx context.Context, bucket string, path string, size int64, contentType string, partURLs []string) error {
+	url := fmt.Sprintf("%s/%s", strings.TrimSuffix(c.transport.BaseURL, "/"), resumableUploadPath)
+	req, err := c.transport.NewRequest(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Tus-Resumable", tusResumableVersion)
+	req.Header.Set("Upload-Metadata", uploadMetadata(bucket, path, contentType))
+	req.Header.Set("Upload-Concat", "final;"+strings.Join(partURLs, " "))
+
+	res, err := c.transport.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		return fmt.Errorf("storage: resumable upload concatenation failed with status %d", res.StatusCode)
+	}
+	return nil
+}
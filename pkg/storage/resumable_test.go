@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/semvis123/supabase-go/pkg/transport"
+)
+
+func newTestClient(baseURL string) *client {
+	t := transport.New(transport.Config{BaseURL: baseURL, APIKey: "test-key"})
+	return &client{transport: t}
+}
+
+// tusUpload is a single in-memory TUS resource backing the test server's
+// POST/HEAD/PATCH handlers below.
+type tusUpload struct {
+	size   int64
+	offset int64
+	// conflictOnce, if set, makes the next PATCH return 409 with a server
+	// offset of conflictOffset instead of applying the chunk.
+	conflictOnce   bool
+	conflictOffset int64
+	// stallOffset, if >= 0, makes PATCH report no progress (offset
+	// unchanged) instead of advancing - simulating a server that never
+	// converges.
+	stallOffset int64
+}
+
+func newTusTestServer(t *testing.T, upload *tusUpload) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/"+resumableUploadPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Location", "http://"+r.Host+"/"+resumableUploadPath+"/upload1")
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("/"+resumableUploadPath+"/upload1", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodHead:
+			w.Header().Set("Upload-Offset", strconv.FormatInt(upload.offset, 10))
+			w.WriteHeader(http.StatusNoContent)
+		case http.MethodPatch:
+			if upload.conflictOnce {
+				upload.conflictOnce = false
+				upload.offset = upload.conflictOffset
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			chunkLen := r.ContentLength
+			if upload.stallOffset >= 0 {
+				w.Header().Set("Upload-Offset", strconv.FormatInt(upload.stallOffset, 10))
+			} else {
+				upload.offset += chunkLen
+				w.Header().Set("Upload-Offset", strconv.FormatInt(upload.offset, 10))
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestUploadResumableSingleChunk(t *testing.T) {
+	upload := &tusUpload{stallOffset: -1}
+	srv := newTusTestServer(t, upload)
+	c := newTestClient(srv.URL)
+
+	data := []byte("hello world")
+	err := c.UploadResumable(context.Background(), "bucket", "object", bytesReaderAt(data), int64(len(data)), UploadResumableOptions{})
+	if err != nil {
+		t.Fatalf("UploadResumable: %v", err)
+	}
+	if upload.offset != int64(len(data)) {
+		t.Fatalf("server offset = %d, want %d", upload.offset, len(data))
+	}
+}
+
+func TestUploadResumableResyncsOn409(t *testing.T) {
+	upload := &tusUpload{stallOffset: -1, conflictOnce: true, conflictOffset: 4}
+	srv := newTusTestServer(t, upload)
+	c := newTestClient(srv.URL)
+
+	data := []byte("hello world")
+	err := c.UploadResumable(context.Background(), "bucket", "object", bytesReaderAt(data), int64(len(data)), UploadResumableOptions{})
+	if err != nil {
+		t.Fatalf("UploadResumable: %v", err)
+	}
+	if upload.offset != int64(len(data)) {
+		t.Fatalf("server offset = %d, want %d", upload.offset, len(data))
+	}
+}
+
+func TestUploadResumableRejectsNonAdvancingOffset(t *testing.T) {
+	data := []byte("hello world")
+	upload := &tusUpload{stallOffset: 0}
+	srv := newTusTestServer(t, upload)
+	c := newTestClient(srv.URL)
+
+	err := c.UploadResumable(context.Background(), "bucket", "object", bytesReaderAt(data), int64(len(data)), UploadResumableOptions{})
+	if err == nil {
+		t.Fatal("UploadResumable: want error when the server reports no progress, got nil")
+	}
+}
+
+func TestUploadResumableRejectsShortSource(t *testing.T) {
+	upload := &tusUpload{stallOffset: -1}
+	srv := newTusTestServer(t, upload)
+	c := newTestClient(srv.URL)
+
+	// Declare a size larger than the source actually has.
+	data := []byte("short")
+	err := c.UploadResumable(context.Background(), "bucket", "object", bytesReaderAt(data), int64(len(data))+10, UploadResumableOptions{})
+	if err == nil {
+		t.Fatal("UploadResumable: want error for a source shorter than the declared size, got nil")
+	}
+}
+
+type bytesReaderAt []byte
+
+func (b bytesReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off >= int64(len(b)) {
+		return 0, io.EOF
+	}
+	n := copy(p, b[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
@@ -0,0 +1,75 @@
+// Package storage implements the Supabase Storage (storage/v1) client:
+// object upload/download/removal against a bucket.
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/semvis123/supabase-go/pkg/transport"
+)
+
+const storageEndpoint = "storage/v1"
+
+// Client is the public surface of the storage sub-package.
+type Client interface {
+	Upload(ctx context.Context, bucket string, path string, contentType string, body io.Reader) error
+	Download(ctx context.Context, bucket string, path string) (io.ReadCloser, error)
+	Remove(ctx context.Context, bucket string, paths []string) error
+	// UploadResumable uploads large objects via the TUS resumable upload
+	// protocol, see resumable.go.
+	UploadResumable(ctx context.Context, bucket string, path string, src io.ReaderAt, size int64, opts UploadResumableOptions) error
+}
+
+type client struct {
+	transport *transport.Client
+}
+
+// New builds the default storage.Client backed by t.
+func New(t *transport.Client) Client {
+	return &client{transport: t}
+}
+
+func (c *client) objectURL(bucket string, path string) string {
+	return fmt.Sprintf("%s/%s/object/%s/%s", strings.TrimSuffix(c.transport.BaseURL, "/"), storageEndpoint, bucket, strings.TrimPrefix(path, "/"))
+}
+
+func (c *client) Upload(ctx context.Context, bucket string, path string, contentType string, body io.Reader) error {
+	req, err := c.transport.NewRequest(ctx, http.MethodPost, c.objectURL(bucket, path), body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	return c.transport.SendRequest(ctx, req, nil)
+}
+
+func (c *client) Download(ctx context.Context, bucket string, path string) (io.ReadCloser, error) {
+	req, err := c.transport.NewRequest(ctx, http.MethodGet, c.objectURL(bucket, path), nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.transport.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		return nil, fmt.Errorf("storage: download failed with status %d", res.StatusCode)
+	}
+	return res.Body, nil
+}
+
+func (c *client) Remove(ctx context.Context, bucket string, paths []string) error {
+	url := fmt.Sprintf("%s/%s/object/%s", strings.TrimSuffix(c.transport.BaseURL, "/"), storageEndpoint, bucket)
+	req, err := c.transport.NewRequest(ctx, http.MethodDelete, url, transport.JSONBody(struct {
+		Prefixes []string `json:"prefixes"`
+	}{Prefixes: paths}))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return c.transport.SendRequest(ctx, req, nil)
+}
@@ -0,0 +1,172 @@
+// Package transport holds the HTTP plumbing shared by every Supabase
+// sub-client (auth, storage, admin): request signing, error decoding,
+// context propagation and a pluggable middleware chain. Sub-packages hold a
+// *Client rather than talking to net/http directly so that behaviour like
+// retries or logging, added once here, applies everywhere.
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ErrorResponse is the shape of the error body Supabase's HTTP APIs return.
+type ErrorResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"msg"`
+}
+
+func (err *ErrorResponse) Error() string {
+	return err.Message
+}
+
+// Middleware wraps an http.RoundTripper to add cross-cutting behaviour such
+// as logging, metrics, retries or rate-limiting.
+type Middleware func(http.RoundTripper) http.RoundTripper
+
+// Config holds the per-project settings every sub-client needs to reach the
+// Supabase API.
+type Config struct {
+	BaseURL    string
+	APIKey     string
+	Headers    map[string]string
+	HTTPClient *http.Client
+}
+
+// Client is the shared, transport-level HTTP client. auth.Client,
+// storage.Client and admin.Client are all built on top of one.
+type Client struct {
+	BaseURL string
+	APIKey  string
+	Headers map[string]string
+
+	HTTPClient    *http.Client
+	baseTransport http.RoundTripper
+	middlewares   []Middleware
+}
+
+// New builds a transport.Client from cfg, defaulting HTTPClient to a
+// one-minute timeout if none is given.
+func New(cfg Config) *Client {
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: time.Minute}
+	}
+	return &Client{
+		BaseURL:    cfg.BaseURL,
+		APIKey:     cfg.APIKey,
+		Headers:    cfg.Headers,
+		HTTPClient: httpClient,
+	}
+}
+
+// Use appends a middleware to the transport chain and rebuilds
+// HTTPClient.Transport. Middlewares run in the order they were registered,
+// with the first one registered being the outermost.
+func (c *Client) Use(mw Middleware) {
+	c.middlewares = append(c.middlewares, mw)
+	c.rebuildTransport()
+}
+
+func (c *Client) rebuildTransport() {
+	rt := c.baseTransport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		rt = c.middlewares[i](rt)
+	}
+	c.HTTPClient.Transport = rt
+}
+
+// JSONBody marshals v and returns it as a request body, panicking if v
+// cannot be marshalled since that indicates a programmer error in a
+// hand-written request struct, not a runtime condition callers can recover
+// from.
+func JSONBody(v interface{}) io.Reader {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("transport: failed to marshal request body: %v", err))
+	}
+	return bytes.NewReader(data)
+}
+
+func injectAuthorizationHeader(req *http.Request, value string) {
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", value))
+}
+
+// NewRequest builds a request authorized as the transport's own API key,
+// with the apikey header and any configured custom Headers already set -
+// whether the caller then sends it via SendRequest or the lower-level Do.
+func (c *Client) NewRequest(ctx context.Context, method, url string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	injectAuthorizationHeader(req, c.APIKey)
+	req.Header.Set("apikey", c.APIKey)
+	for key, val := range c.Headers {
+		req.Header.Set(key, val)
+	}
+	return req, nil
+}
+
+// Do sends req using the transport's configured http.Client (including any
+// middleware), for callers that need raw access to the response - e.g.
+// streaming a download body or inspecting response headers.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	return c.HTTPClient.Do(req)
+}
+
+// SendRequest decodes a successful response into v, or returns an
+// *ErrorResponse for non-2xx responses whose body decodes as JSON; a
+// non-2xx response with any other body yields a plain error naming the
+// status code.
+func (c *Client) SendRequest(ctx context.Context, req *http.Request, v interface{}) error {
+	var errRes ErrorResponse
+	hasCustomError, err := c.SendCustomRequest(ctx, req, v, &errRes)
+	if err != nil {
+		return err
+	} else if hasCustomError {
+		return &errRes
+	}
+	return nil
+}
+
+// SendCustomRequest is like SendRequest but lets the caller supply its own
+// error type instead of the default ErrorResponse.
+func (c *Client) SendCustomRequest(ctx context.Context, req *http.Request, successValue interface{}, errorValue interface{}) (bool, error) {
+	req = req.WithContext(ctx)
+	req.Header.Set("apikey", c.APIKey)
+	for key, val := range c.Headers {
+		req.Header.Set(key, val)
+	}
+
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+
+	defer res.Body.Close()
+	statusOK := res.StatusCode >= http.StatusOK && res.StatusCode < 300
+	if !statusOK {
+		if err = json.NewDecoder(res.Body).Decode(&errorValue); err == nil {
+			return true, nil
+		}
+		return false, fmt.Errorf("unknown, status code: %d", res.StatusCode)
+	} else if res.StatusCode != http.StatusNoContent {
+		if err = json.NewDecoder(res.Body).Decode(&successValue); err != nil {
+			return false, err
+		}
+	}
+
+	return false, nil
+}
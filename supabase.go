@@ -1,13 +1,20 @@
+// Package supabase is a thin facade over the auth, storage, realtime and
+// admin sub-packages: it wires them to a shared transport.Client so callers
+// get one Client with BaseURL/apiKey configured once, while each subsystem
+// lives in its own package and can be imported (or faked) independently.
 package supabase
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
-	"time"
 
-	postgrest "github.com/semvis123/postgrest-go"
+	postgrest "github.com/supabase-community/postgrest-go"
+	"github.com/semvis123/supabase-go/pkg/admin"
+	"github.com/semvis123/supabase-go/pkg/auth"
+	"github.com/semvis123/supabase-go/pkg/realtime"
+	"github.com/semvis123/supabase-go/pkg/storage"
+	"github.com/semvis123/supabase-go/pkg/transport"
 )
 
 const (
@@ -18,140 +25,64 @@ const (
 	RealtimeEndpoint = "realtime/v1"
 )
 
+// ErrorResponse is re-exported from transport for backwards compatibility;
+// use transport.ErrorResponse directly in new code.
+type ErrorResponse = transport.ErrorResponse
+
+// RoundTripperMiddleware is re-exported from transport for backwards
+// compatibility; use transport.Middleware directly in new code.
+type RoundTripperMiddleware = transport.Middleware
+
 type Client struct {
-	BaseURL string
-	// apiKey can be a client API key or a service key
-	apiKey     string
+	BaseURL    string
 	Headers    map[string]string
 	HTTPClient *http.Client
-	Admin      *Admin
-	Auth       *Auth
-	Storage    *Storage
-	Realtime   *Realtime
+	Admin      admin.Client
+	Auth       auth.Client
+	Storage    storage.Client
+	Realtime   realtime.Client
 	DB         *postgrest.Client
-}
 
-type ErrorResponse struct {
-	Code    int    `json:"code"`
-	Message string `json:"msg"`
+	transport *transport.Client
 }
 
-func (err *ErrorResponse) Error() string {
-	return err.Message
+// Use appends a middleware to the client's shared transport chain. Every
+// sub-client (Auth, Storage, Admin) sends requests through this transport,
+// so the middleware applies to all of them.
+func (c *Client) Use(mw RoundTripperMiddleware) {
+	c.transport.Use(mw)
 }
 
 // CreateClient creates a new Supabase client
 func CreateClient(baseURL string, supabaseKey string, debug ...bool) *Client {
-	urlString := fmt.Sprintf("%s/%s/", baseURL, RestEndpoint)
-	_, err := url.Parse(urlString)
-	if err != nil {
-		panic(err)
-	}
-
-	client := &Client{
-		BaseURL:  baseURL,
-		apiKey:   supabaseKey,
-		Headers:  nil,
-		Admin:    &Admin{},
-		Auth:     &Auth{},
-		Storage:  &Storage{},
-		Realtime: &Realtime{},
-		HTTPClient: &http.Client{
-			Timeout: time.Minute,
-		},
-		DB: postgrest.NewClient(
-			urlString,
-			"public",
-			map[string]string{
-				"apikey":        supabaseKey,
-				"Authorization": "Bearer " + supabaseKey,
-			},
-		),
-	}
-	client.Admin.client = client
-	client.Admin.serviceKey = supabaseKey
-	client.Auth.client = client
-	client.Storage.client = client
-	client.Realtime.client = client
-	return client
+	return CreateClientWithHeaders(baseURL, supabaseKey, map[string]string{}, debug...)
 }
 
-// CreateClient creates a new Supabase client
+// CreateClientWithHeaders creates a new Supabase client that sends the
+// given extra headers on every request.
 func CreateClientWithHeaders(baseURL string, supabaseKey string, headers map[string]string, debug ...bool) *Client {
-	urlString := fmt.Sprintf("%s/%s/", baseURL, RestEndpoint)
-	_, err := url.Parse(urlString)
-	if err != nil {
+	restURL := fmt.Sprintf("%s/%s/", baseURL, RestEndpoint)
+	if _, err := url.Parse(restURL); err != nil {
 		panic(err)
 	}
 	headers["apikey"] = supabaseKey
 	headers["Authorization"] = "Bearer " + supabaseKey
 
-	client := &Client{
-		BaseURL:  baseURL,
-		apiKey:   supabaseKey,
-		Headers:  headers,
-		Admin:    &Admin{},
-		Auth:     &Auth{},
-		Storage:  &Storage{},
-		Realtime: &Realtime{},
-		HTTPClient: &http.Client{
-			Timeout: time.Minute,
-		},
-		DB: postgrest.NewClient(
-			urlString,
-			"public",
-			headers,
-		),
-	}
-	client.Admin.client = client
-	client.Admin.serviceKey = supabaseKey
-	client.Auth.client = client
-	client.Storage.client = client
-	client.Realtime.client = client
-	return client
-}
-
-func injectAuthorizationHeader(req *http.Request, value string) {
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", value))
-}
-
-func (c *Client) sendRequest(req *http.Request, v interface{}) error {
-	var errRes ErrorResponse
-	hasCustomError, err := c.sendCustomRequest(req, v, &errRes)
-
-	if err != nil {
-		return err
-	} else if hasCustomError {
-		return &errRes
-	}
-
-	return nil
-}
-
-func (c *Client) sendCustomRequest(req *http.Request, successValue interface{}, errorValue interface{}) (bool, error) {
-	req.Header.Set("apikey", c.apiKey)
-	for key, val := range c.Headers {
-		req.Header.Set(key, val)
+	t := transport.New(transport.Config{
+		BaseURL: baseURL,
+		APIKey:  supabaseKey,
+		Headers: headers,
+	})
+
+	return &Client{
+		BaseURL:    baseURL,
+		Headers:    headers,
+		HTTPClient: t.HTTPClient,
+		Admin:      admin.New(t, supabaseKey),
+		Auth:       auth.New(t),
+		Storage:    storage.New(t),
+		Realtime:   realtime.New(baseURL, supabaseKey),
+		DB:         postgrest.NewClient(restURL, "public", headers),
+		transport:  t,
 	}
-
-	res, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return true, err
-	}
-
-	defer res.Body.Close()
-	statusOK := res.StatusCode >= http.StatusOK && res.StatusCode < 300
-	if !statusOK {
-		if err = json.NewDecoder(res.Body).Decode(&errorValue); err == nil {
-			return true, nil
-		}
-
-		return false, fmt.Errorf("unknown, status code: %d", res.StatusCode)
-	} else if res.StatusCode != http.StatusNoContent {
-		if err = json.NewDecoder(res.Body).Decode(&successValue); err != nil {
-			return false, err
-		}
-	}
-
-	return false, nil
 }